@@ -14,7 +14,7 @@ import (
 )
 
 func init() {
-	dbmap.RegisterMapper(jsonMapper{})
+	dbmap.RegisterMapperPriority(jsonMapper{}, dbmap.PriorityJSON)
 }
 
 var jsonBufPool = &sync.Pool{
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -14,7 +15,39 @@ var (
 	fieldNameRe    = regexp.MustCompile(`([A-Z]+)([^A-Z]*)`)
 )
 
-var mappers []Mapper
+// Priorities of the built-in mappers. Mappers with a lower priority are tried
+// first.
+const (
+	// PriorityDefault is the priority of mappers registered with
+	// RegisterMapper, which makes them take precedence over the built-ins.
+	PriorityDefault    = 0
+	PrioritySQLScanner = 100
+	PriorityJSON       = 200
+	PriorityNative     = 300
+)
+
+type prioritizedMapper struct {
+	Mapper
+	priority int
+}
+
+// The registered mappers, sorted by priority.
+var mappers []prioritizedMapper
+
+// A NameMapper derives the name of a database column from the name of a
+// struct field that has no db tag set.
+type NameMapper func(fieldName string) string
+
+// NameMapperSnakeCase converts the field name to snake case, e.g. UserID
+// becomes user_id. This is the default.
+func NameMapperSnakeCase(fieldName string) string {
+	return defaultDBName(fieldName)
+}
+
+// NameMapperIdentity uses the field name verbatim, e.g. UserID stays UserID.
+func NameMapperIdentity(fieldName string) string {
+	return fieldName
+}
 
 type Mapper interface {
 	// Checks whether this mapper is able to handle the specified type.
@@ -31,8 +64,21 @@ type Mapper interface {
 	Copy(target, scanned interface{})
 }
 
+// RegisterMapper registers the mapper with the default priority.
 func RegisterMapper(mapper Mapper) {
-	mappers = append([]Mapper{mapper}, mappers...)
+	RegisterMapperPriority(mapper, PriorityDefault)
+}
+
+// RegisterMapperPriority registers the mapper with the specified priority.
+// Mappers with a lower priority are tried first. Of mappers with an equal
+// priority, the one that was registered last is tried first.
+func RegisterMapperPriority(mapper Mapper, priority int) {
+	i := sort.Search(len(mappers), func(i int) bool {
+		return mappers[i].priority >= priority
+	})
+	mappers = append(mappers, prioritizedMapper{})
+	copy(mappers[i+1:], mappers[i:])
+	mappers[i] = prioritizedMapper{Mapper: mapper, priority: priority}
 }
 
 // A Mapping is translates queried database rows to annotated structs.
@@ -43,6 +89,11 @@ type Mapping struct {
 	// values the names of the structfields.
 	dbToStruct map[string]string
 
+	// A map where the keys are the positional indices of the database
+	// columns and the values the names of the structfields. Fields are bound
+	// by position using tags of the form `db:"#0"`.
+	positional map[int]string
+
 	// The mappers that will be used for each field.
 	mapping map[string]Mapper
 
@@ -51,8 +102,19 @@ type Mapping struct {
 	scanNesting map[string]func(struc reflect.Value) (nestedStruct reflect.Value)
 }
 
-// StructMapping creates the mapping for the specified struct.
+// StructMapping creates the mapping for the specified struct. Database column
+// names of fields without a db tag are inferred using NameMapperSnakeCase.
 func StructMapping(struc interface{}) (Mapping, error) {
+	return StructMappingWith(struc, NameMapperSnakeCase)
+}
+
+// StructMappingWith is like StructMapping, but infers the database column
+// names of fields without a db tag using the specified NameMapper. A nil
+// NameMapper is the same as NameMapperSnakeCase.
+func StructMappingWith(struc interface{}, nameMapper NameMapper) (Mapping, error) {
+	if nameMapper == nil {
+		nameMapper = NameMapperSnakeCase
+	}
 	structType := reflect.TypeOf(struc)
 	if structType.Kind() != reflect.Struct {
 		return Mapping{}, fmt.Errorf("argument is not a struct, actually is %v", structType.Kind())
@@ -61,18 +123,33 @@ func StructMapping(struc interface{}) (Mapping, error) {
 	mapping := Mapping{
 		structType:  structType,
 		dbToStruct:  map[string]string{},
+		positional:  map[int]string{},
 		mapping:     map[string]Mapper{},
 		scanNesting: map[string]func(reflect.Value) reflect.Value{},
 	}
 	noNesting := func(s reflect.Value) reflect.Value {
 		return s
 	}
-	if err := mapping.mapStruct(mapping.structType, noNesting); err != nil {
+	if err := mapping.mapStruct(mapping.structType, noNesting, nameMapper); err != nil {
 		return Mapping{}, err
 	}
+	if len(mapping.dbToStruct) > 0 && len(mapping.positional) > 0 {
+		return Mapping{}, fmt.Errorf("mixed named and positional mappings on %v", mapping.structType)
+	}
 	return mapping, nil
 }
 
+// structMappingOf creates the mapping for the type parameter T. Non-struct
+// type arguments, including interfaces, return an error instead of panicking
+// in StructMapping.
+func structMappingOf[T any]() (Mapping, error) {
+	structType := reflect.TypeOf((*T)(nil)).Elem()
+	if structType.Kind() != reflect.Struct {
+		return Mapping{}, fmt.Errorf("type argument is not a struct, actually is %v", structType.Kind())
+	}
+	return StructMapping(reflect.Zero(structType).Interface())
+}
+
 // MustStructMapping is like StructMapping, but panics if an error occurs.
 // Usefull for one-time initialization at the start of the program.
 func MustStructMapping(struc interface{}) Mapping {
@@ -83,7 +160,17 @@ func MustStructMapping(struc interface{}) Mapping {
 	return mapping
 }
 
-func (mapping *Mapping) mapStruct(structType reflect.Type, nesting func(reflect.Value) reflect.Value) error {
+// MustStructMappingWith is like StructMappingWith, but panics if an error
+// occurs.
+func MustStructMappingWith(struc interface{}, nameMapper NameMapper) Mapping {
+	mapping, err := StructMappingWith(struc, nameMapper)
+	if err != nil {
+		panic(err)
+	}
+	return mapping
+}
+
+func (mapping *Mapping) mapStruct(structType reflect.Type, nesting func(reflect.Value) reflect.Value, nameMapper NameMapper) error {
 outer:
 	for i := 0; i < structType.NumField(); i++ {
 		field := structType.Field(i)
@@ -94,29 +181,49 @@ outer:
 			continue
 		}
 
+		if field.PkgPath != "" && !field.Anonymous {
+			// Unexported fields can not be scanned into, skip them. This
+			// also skips the internals of embedded third-party types.
+			continue
+		}
+
 		if dbName == "" {
 			// No name set? Check whether this is an embedded field and
 			// recursively map all of its fields.
 			if field.Anonymous && field.Type.Kind() == reflect.Struct {
-				mapping.mapStruct(field.Type, func(s reflect.Value) reflect.Value {
+				err := mapping.mapStruct(field.Type, func(s reflect.Value) reflect.Value {
 					return nesting(s).FieldByName(field.Name)
-				})
+				}, nameMapper)
+				if err != nil {
+					return err
+				}
 				continue
 			}
 
 			// The field is not an embedded struct and no name is set, infer
 			// the db name from the struct field name.
-			dbName = defaultDBName(field.Name)
+			dbName = nameMapper(field.Name)
 		}
 
-		if _, ok := mapping.dbToStruct[dbName]; ok {
-			return fmt.Errorf("duplicate mapping for %q on %v", dbName, mapping.structType)
+		if strings.HasPrefix(dbName, "#") {
+			index, err := strconv.Atoi(dbName[1:])
+			if err != nil || !isDigits(dbName[1:]) {
+				return fmt.Errorf("invalid positional mapping %q on %v", dbName, mapping.structType)
+			}
+			if _, ok := mapping.positional[index]; ok {
+				return fmt.Errorf("duplicate mapping for %q on %v", dbName, mapping.structType)
+			}
+			mapping.positional[index] = field.Name
+		} else {
+			if _, ok := mapping.dbToStruct[dbName]; ok {
+				return fmt.Errorf("duplicate mapping for %q on %v", dbName, mapping.structType)
+			}
+			mapping.dbToStruct[dbName] = field.Name
 		}
-		mapping.dbToStruct[dbName] = field.Name
 		mapping.scanNesting[field.Name] = nesting
 		for _, mapper := range mappers {
 			if mapper.Accepts(field.Type) {
-				mapping.mapping[field.Name] = mapper
+				mapping.mapping[field.Name] = mapper.Mapper
 				continue outer
 			}
 		}
@@ -127,16 +234,55 @@ outer:
 
 // ScanRow scans the current value of the row into the target struct.
 func (mapping Mapping) ScanRow(target interface{}, row Row, scanOrder ...string) error {
+	fields := make([]string, len(scanOrder))
+	for i, col := range scanOrder {
+		fields[i] = mapping.dbToStruct[col]
+	}
+	return mapping.scanFields(target, row, fields)
+}
+
+// ScanRowPositional scans the current value of the row into the target
+// struct, binding columns by their index rather than by their name. Only
+// fields tagged with a positional index, like `db:"#0"`, are populated.
+func (mapping Mapping) ScanRowPositional(target interface{}, row Row, numColumns int) error {
+	indices := make([]int, 0, len(mapping.positional))
+	for index := range mapping.positional {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+
+	fields := make([]string, numColumns)
+	for _, index := range indices {
+		if index >= numColumns {
+			return fmt.Errorf("positional mapping #%d is out of range for %d columns", index, numColumns)
+		}
+		fields[index] = mapping.positional[index]
+	}
+	return mapping.scanFields(target, row, fields)
+}
+
+// scanRow scans the row using either the named or the positional mapping,
+// depending on how the struct was annotated.
+func (mapping Mapping) scanRow(target interface{}, row Row, cols []string) error {
+	if len(mapping.positional) > 0 {
+		return mapping.ScanRowPositional(target, row, len(cols))
+	}
+	return mapping.ScanRow(target, row, cols...)
+}
+
+// scanFields scans the row into the target. Each element of fields holds the
+// name of the struct field the column at that index is scanned into, or an
+// empty string if the column is not mapped.
+func (mapping Mapping) scanFields(target interface{}, row Row, fields []string) error {
 	if t := reflect.TypeOf(target).Elem(); !mapping.structType.ConvertibleTo(t) {
 		return fmt.Errorf("mapping type (%v) is not convertible to the scan target (%v)", mapping.structType, t)
 	}
 
 	tarval := reflect.Indirect(reflect.ValueOf(target))
 
-	scan := make([]interface{}, len(scanOrder))
-	for i, col := range scanOrder {
-		strucName, ok := mapping.dbToStruct[col]
-		if !ok {
+	scan := make([]interface{}, len(fields))
+	for i, strucName := range fields {
+		if strucName == "" {
 			continue
 		}
 		field := mapping.scanNesting[strucName](tarval).FieldByName(strucName)
@@ -151,9 +297,8 @@ func (mapping Mapping) ScanRow(target interface{}, row Row, scanOrder ...string)
 		return err
 	}
 
-	for i, col := range scanOrder {
-		strucName, ok := mapping.dbToStruct[col]
-		if !ok {
+	for i, strucName := range fields {
+		if strucName == "" {
 			continue
 		}
 		mapping.mapping[strucName].Copy(mapping.scanNesting[strucName](tarval).FieldByName(strucName).Addr().Interface(), scan[i])
@@ -172,7 +317,7 @@ func (mapping Mapping) ScanOne(target interface{}, rows Rows) (bool, error) {
 	if !rows.Next() {
 		return false, nil
 	}
-	if err := mapping.ScanRow(target, rows, cols...); err != nil {
+	if err := mapping.scanRow(target, rows, cols); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -195,7 +340,7 @@ func (mapping Mapping) ScanStream(rows Rows) <-chan interface{} {
 
 		for rows.Next() {
 			scan := reflect.New(mapping.structType)
-			if err := mapping.ScanRow(scan.Interface(), rows, cols...); err != nil {
+			if err := mapping.scanRow(scan.Interface(), rows, cols); err != nil {
 				out <- err
 				return
 			}
@@ -212,17 +357,44 @@ func (mapping Mapping) ScanStream(rows Rows) <-chan interface{} {
 // slice of the type that was used to create this mapping. The cursor is always
 // closed.  If an error occurs, none of the scanned values are returned.
 func (mapping Mapping) ScanAll(rows Rows) (interface{}, error) {
+	slice, err := mapping.ScanAllPartial(rows)
+	if err != nil {
+		return nil, err
+	}
+	return slice, nil
+}
+
+// ScanAllPartial is like ScanAll, but if an error occurs, the rows that were
+// scanned before the failure are returned alongside the error. The returned
+// slice may therefore be incomplete when the error is non-nil.
+func (mapping Mapping) ScanAllPartial(rows Rows) (interface{}, error) {
 	stream := mapping.ScanStream(rows)
 	slice := reflect.MakeSlice(reflect.SliceOf(mapping.structType), 0, 1)
 	for elem := range stream {
 		if err, ok := elem.(error); ok {
-			return nil, err
+			return slice.Interface(), err
 		}
 		slice = reflect.Append(slice, reflect.ValueOf(elem))
 	}
 	return slice.Interface(), nil
 }
 
+// ScanAllOf is like ScanAll, but creates the mapping for T and returns a typed
+// slice. T may also be an anonymous struct type, which is convenient for
+// one-off queries.
+func ScanAllOf[T any](rows Rows) ([]T, error) {
+	mapping, err := structMappingOf[T]()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	results, err := mapping.ScanAll(rows)
+	if err != nil {
+		return nil, err
+	}
+	return results.([]T), nil
+}
+
 func (mapping Mapping) String() string {
 	mapperStrings := make([]string, 0, len(mapping.mapping))
 	for col, mapper := range mapping.mapping {
@@ -262,3 +434,12 @@ func defaultDBName(fieldName string) string {
 	}
 	return strings.Join(parts, "_")
 }
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
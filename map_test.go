@@ -1,8 +1,11 @@
 package dbmap
 
 import (
+	"database/sql"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -165,6 +168,54 @@ func TestDuplicateMappingEmbedded(t *testing.T) {
 	}
 }
 
+func TestEmbeddedThirdPartyType(t *testing.T) {
+	rows := &TestRows{
+		Current: -1,
+		Rows: []TestRow{
+			{"id": 42},
+		},
+	}
+
+	type MyMutexStruct struct {
+		sync.Mutex
+		ID int `db:"id"`
+	}
+	type MyTimeStruct struct {
+		time.Time
+		ID int `db:"id"`
+	}
+
+	for _, struc := range []interface{}{MyMutexStruct{}, MyTimeStruct{}} {
+		t.Run(reflect.TypeOf(struc).Name(), func(t *testing.T) {
+			rows.Current = -1
+			mapping, err := StructMapping(struc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := testPair(mapping.dbToStruct, "id", "ID"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := mapping.ScanAll(rows); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestUnexportedField(t *testing.T) {
+	type MyStruct struct {
+		ID     int `db:"id"`
+		secret int
+	}
+	mapping, err := StructMapping(MyStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mapping.dbToStruct["secret"]; ok {
+		t.Fatalf("unexported field was mapped")
+	}
+}
+
 func TestDefaultDBName(t *testing.T) {
 	tt := []struct {
 		FieldName string
@@ -218,3 +269,361 @@ func TestDefaultNameMapping(t *testing.T) {
 		t.Fatalf("Field was not scanned")
 	}
 }
+
+func TestPositionalMapping(t *testing.T) {
+	rows := &TestRows{
+		Current: -1,
+		Rows: []TestRow{
+			{"col1": 42, "col2": "foo"},
+			{"col1": 43, "col2": "bar"},
+		},
+	}
+
+	type MyStruct struct {
+		ID   int    `db:"#0"`
+		Name string `db:"#1"`
+	}
+
+	mapping, err := StructMapping(MyStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := mapping.ScanAll(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slice := results.([]MyStruct)
+
+	if len(slice) != len(rows.Rows) {
+		t.Fatalf("Number of returned rows, %v,  does not match the input, %v", len(slice), len(rows.Rows))
+	}
+	for i, target := range slice {
+		if target.ID != rows.Rows[i]["col1"] {
+			t.Fatalf("value ID was not scanned at index %d", i)
+		}
+		if target.Name != rows.Rows[i]["col2"] {
+			t.Fatalf("value Name was not scanned at index %d", i)
+		}
+	}
+}
+
+func TestScanRowPositional(t *testing.T) {
+	row := TestRow{"col1": 42, "col2": "foo"}
+
+	type MyStruct struct {
+		ID   int    `db:"#0"`
+		Name string `db:"#1"`
+	}
+
+	mapping, err := StructMapping(MyStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := MyStruct{}
+	if err := mapping.ScanRowPositional(&target, row, len(row)); err != nil {
+		t.Fatal(err)
+	}
+	if target.ID != row["col1"] {
+		t.Fatalf("value ID was not scanned")
+	}
+	if target.Name != row["col2"] {
+		t.Fatalf("value Name was not scanned")
+	}
+
+	err = mapping.ScanRowPositional(&MyStruct{}, row, 1)
+	if err == nil || !strings.Contains(err.Error(), "out of range for 1 columns") {
+		t.Fatalf("expected an out of range error, got %v", err)
+	}
+
+	// The lowest out of range index is always reported.
+	for i := 0; i < 10; i++ {
+		err = mapping.ScanRowPositional(&MyStruct{}, row, 0)
+		if err == nil || err.Error() != "positional mapping #0 is out of range for 0 columns" {
+			t.Fatalf("expected an out of range error for #0, got %v", err)
+		}
+	}
+}
+
+func TestPositionalMappingInvalid(t *testing.T) {
+	tt := []interface{}{
+		struct {
+			Foo int `db:"#x"`
+		}{},
+		struct {
+			Foo int `db:"#"`
+		}{},
+		struct {
+			Foo int `db:"#+1"`
+		}{},
+		struct {
+			Foo int `db:"#-0"`
+		}{},
+	}
+	for _, struc := range tt {
+		t.Run(reflect.TypeOf(struc).Field(0).Tag.Get("db"), func(t *testing.T) {
+			if _, err := StructMapping(struc); err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
+
+func TestPositionalMappingEmbedded(t *testing.T) {
+	type MyInvalidEmbeddedStruct struct {
+		Foo int `db:"#x"`
+	}
+	type MyInvalidStruct struct {
+		MyInvalidEmbeddedStruct
+	}
+	if _, err := StructMapping(MyInvalidStruct{}); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	type MyDuplicateEmbeddedStruct struct {
+		Foo int `db:"#0"`
+		Bar int `db:"#0"`
+	}
+	type MyDuplicateStruct struct {
+		MyDuplicateEmbeddedStruct
+	}
+	if _, err := StructMapping(MyDuplicateStruct{}); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestPositionalMappingMixed(t *testing.T) {
+	type MyStruct struct {
+		ID   int    `db:"#0"`
+		Name string `db:"name"`
+	}
+	if _, err := StructMapping(MyStruct{}); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestPositionalMappingDuplicate(t *testing.T) {
+	type MyStruct struct {
+		Foo int `db:"#0"`
+		Bar int `db:"#0"`
+	}
+	if _, err := StructMapping(MyStruct{}); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestNameMapperIdentity(t *testing.T) {
+	rows := &TestRows{
+		Current: -1,
+		Rows: []TestRow{
+			{"UserID": 42, "JSONThing": "foo"},
+		},
+	}
+
+	type MyStruct struct {
+		UserID    int
+		JSONThing string
+	}
+
+	mapping, err := StructMappingWith(MyStruct{}, NameMapperIdentity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := testPair(mapping.dbToStruct, "UserID", "UserID"); err != nil {
+		t.Fatal(err)
+	} else if err := testPair(mapping.dbToStruct, "JSONThing", "JSONThing"); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := mapping.ScanAll(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slice := results.([]MyStruct)
+
+	if slice[0].UserID != rows.Rows[0]["UserID"] {
+		t.Fatalf("Field UserID was not scanned")
+	}
+	if slice[0].JSONThing != rows.Rows[0]["JSONThing"] {
+		t.Fatalf("Field JSONThing was not scanned")
+	}
+}
+
+func TestStructMappingWithNilNameMapper(t *testing.T) {
+	type MyStruct struct {
+		UserID int
+	}
+
+	mapping, err := StructMappingWith(MyStruct{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := testPair(mapping.dbToStruct, "user_id", "UserID"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScanAnonymousStruct(t *testing.T) {
+	// An alias does not declare a new type, so this remains anonymous.
+	type anonymous = struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+
+	newRows := func() *TestRows {
+		return &TestRows{
+			Current: -1,
+			Rows: []TestRow{
+				{"id": 1, "name": "foo"},
+				{"id": 2, "name": "bar"},
+			},
+		}
+	}
+	check := func(t *testing.T, rows *TestRows, slice []anonymous) {
+		if len(slice) != len(rows.Rows) {
+			t.Fatalf("Number of returned rows, %v,  does not match the input, %v", len(slice), len(rows.Rows))
+		}
+		for i, target := range slice {
+			if target.ID != rows.Rows[i]["id"] || target.Name != rows.Rows[i]["name"] {
+				t.Fatalf("row %d was not scanned: %+v", i, target)
+			}
+		}
+	}
+
+	if name := reflect.TypeOf(anonymous{}).Name(); name != "" {
+		t.Fatalf("expected an anonymous struct type, got %q", name)
+	}
+
+	t.Run("ScanAll", func(t *testing.T) {
+		rows := newRows()
+		mapping, err := StructMapping(anonymous{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, err := mapping.ScanAll(rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slice, ok := results.([]anonymous)
+		if !ok {
+			t.Fatalf("Invalid return value for ScanAll(): %v", reflect.TypeOf(results))
+		}
+		check(t, rows, slice)
+	})
+
+	t.Run("ScanAllOf", func(t *testing.T) {
+		rows := newRows()
+		slice, err := ScanAllOf[anonymous](rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, rows, slice)
+	})
+}
+
+func TestScanAllOfInvalid(t *testing.T) {
+	rows := &TestRows{
+		Current: -1,
+		Rows: []TestRow{
+			{"foo": 42},
+		},
+	}
+	if _, err := ScanAllOf[interface{}](rows); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if _, err := ScanAllOf[int](rows); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestScanAllPartial(t *testing.T) {
+	rows := &TestRows{
+		Current: -1,
+		Rows: []TestRow{
+			{"foo": int64(1)},
+			{"foo": "not a number"},
+			{"foo": int64(3)},
+		},
+	}
+
+	type MyStruct struct {
+		Foo sql.NullInt64 `db:"foo"`
+	}
+
+	mapping, err := StructMapping(MyStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := mapping.ScanAllPartial(rows)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	slice, ok := results.([]MyStruct)
+	if !ok {
+		t.Fatalf("Invalid return value for ScanAllPartial(): %v", reflect.TypeOf(results))
+	}
+	if len(slice) != 1 {
+		t.Fatalf("Number of returned rows, %v, does not match the rows before the failure, 1", len(slice))
+	}
+	if slice[0].Foo.Int64 != 1 {
+		t.Fatalf("Field Foo was not scanned")
+	}
+}
+
+type priorityTestType struct{}
+
+type priorityTestMapper struct {
+	name string
+}
+
+func (priorityTestMapper) Accepts(fieldType reflect.Type) bool {
+	return fieldType == reflect.TypeOf(priorityTestType{})
+}
+
+func (priorityTestMapper) Receive(field reflect.Value) (receiver interface{}) {
+	return nil
+}
+
+func (priorityTestMapper) Copy(target, scanned interface{}) {}
+
+func TestRegisterMapperPriority(t *testing.T) {
+	defer func(registered []prioritizedMapper) {
+		mappers = registered
+	}(append([]prioritizedMapper(nil), mappers...))
+
+	type MyStruct struct {
+		Foo priorityTestType
+	}
+	mappedBy := func(t *testing.T) string {
+		mapping, err := StructMapping(MyStruct{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mapping.mapping["Foo"].(priorityTestMapper).name
+	}
+
+	RegisterMapperPriority(priorityTestMapper{"low"}, 20)
+	RegisterMapperPriority(priorityTestMapper{"high"}, 10)
+	RegisterMapperPriority(priorityTestMapper{"lowest"}, 30)
+	if name := mappedBy(t); name != "high" {
+		t.Fatalf("unexpected mapper, exp %q, got %q", "high", name)
+	}
+
+	RegisterMapperPriority(priorityTestMapper{"equal"}, 10)
+	if name := mappedBy(t); name != "equal" {
+		t.Fatalf("unexpected mapper, exp %q, got %q", "equal", name)
+	}
+
+	RegisterMapper(priorityTestMapper{"default"})
+	if name := mappedBy(t); name != "default" {
+		t.Fatalf("unexpected mapper, exp %q, got %q", "default", name)
+	}
+
+	for i := 1; i < len(mappers); i++ {
+		if mappers[i-1].priority > mappers[i].priority {
+			t.Fatalf("mappers are not sorted by priority at index %d", i)
+		}
+	}
+}
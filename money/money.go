@@ -0,0 +1,175 @@
+// Package money provides a mapper for scanning monetary columns into integer
+// types holding minor units, such as cents, without the rounding errors of
+// floating point numbers.
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A Mapper scans decimal columns into an int64 based type holding minor units.
+//
+// Decimal values, like "12.34", are scaled by the number of decimal places of
+// the mapper. Integer values are assumed to already be in minor units. Fields
+// that are a pointer to the type are set to nil for NULL values.
+type Mapper struct {
+	typ   reflect.Type
+	scale int
+}
+
+// NewMapper creates a mapper for the specified type, which must have int64 as
+// its underlying type. The scale is the number of decimal places of the minor
+// unit, e.g. 2 for cents. The mapper should be registered with
+// dbmap.RegisterMapper.
+func NewMapper(typ reflect.Type, scale int) (Mapper, error) {
+	if typ.Kind() != reflect.Int64 {
+		return Mapper{}, fmt.Errorf("money: unsupported type %v, its kind should be int64", typ)
+	}
+	if scale < 0 {
+		return Mapper{}, fmt.Errorf("money: invalid scale %d", scale)
+	}
+	return Mapper{typ: typ, scale: scale}, nil
+}
+
+func (m Mapper) Accepts(fieldType reflect.Type) bool {
+	return fieldType == m.typ || fieldType == reflect.PtrTo(m.typ)
+}
+
+func (m Mapper) Receive(field reflect.Value) (receiver interface{}) {
+	return &decimalScanner{
+		Decimal:  Decimal{Scale: m.scale},
+		nullable: field.Kind() == reflect.Ptr,
+	}
+}
+
+func (m Mapper) Copy(target, scanned interface{}) {
+	scanner := scanned.(*decimalScanner)
+	field := reflect.Indirect(reflect.ValueOf(target))
+	if field.Kind() != reflect.Ptr {
+		field.SetInt(scanner.Minor)
+		return
+	}
+	if scanner.null {
+		field.Set(reflect.Zero(field.Type()))
+		return
+	}
+	value := reflect.New(m.typ)
+	value.Elem().SetInt(scanner.Minor)
+	field.Set(value)
+}
+
+// decimalScanner scans a Decimal, allowing NULL values for pointer fields.
+type decimalScanner struct {
+	Decimal
+	nullable bool
+	null     bool
+}
+
+func (s *decimalScanner) Scan(value interface{}) error {
+	if value == nil && s.nullable {
+		s.null = true
+		return nil
+	}
+	return s.Decimal.Scan(value)
+}
+
+// A Decimal is an amount in minor units with a fixed number of decimal places.
+//
+// It implements driver.Valuer, so it can be used to write an amount back to a
+// decimal column, e.g. Decimal{Minor: int64(cents), Scale: 2}.
+type Decimal struct {
+	Minor int64
+	Scale int
+}
+
+func (d *Decimal) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case int64:
+		d.Minor = v
+		return nil
+	case string:
+		minor, err := Parse(v, d.Scale)
+		d.Minor = minor
+		return err
+	case []byte:
+		minor, err := Parse(string(v), d.Scale)
+		d.Minor = minor
+		return err
+	case float64:
+		// The shortest representation that parses back to the same float is
+		// the decimal the database most likely sent.
+		minor, err := Parse(strconv.FormatFloat(v, 'f', -1, 64), d.Scale)
+		d.Minor = minor
+		return err
+	default:
+		return fmt.Errorf("money: can not scan %#v", value)
+	}
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d Decimal) String() string {
+	return Format(d.Minor, d.Scale)
+}
+
+// Parse converts a decimal string to minor units with the specified number of
+// decimal places. An error is returned if the value has more significant
+// decimal places than the scale allows, rather than rounding it.
+func Parse(s string, scale int) (int64, error) {
+	digits, sign := s, ""
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		digits, sign = digits[1:], digits[:1]
+	}
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, fmt.Errorf("money: invalid decimal %q", s)
+	}
+
+	if len(strings.TrimRight(fracPart, "0")) > scale {
+		return 0, fmt.Errorf("money: decimal %q has more than %d decimal places", s, scale)
+	}
+	if len(fracPart) > scale {
+		fracPart = fracPart[:scale]
+	} else {
+		fracPart += strings.Repeat("0", scale-len(fracPart))
+	}
+
+	minor, err := strconv.ParseInt(sign+intPart+fracPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("money: invalid decimal %q: %v", s, err)
+	}
+	return minor, nil
+}
+
+// Format converts minor units to a decimal string with the specified number
+// of decimal places.
+func Format(minor int64, scale int) string {
+	digits := strconv.FormatInt(minor, 10)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		digits, sign = digits[1:], "-"
+	}
+	if scale <= 0 {
+		return sign + digits
+	}
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	i := len(digits) - scale
+	return sign + digits[:i] + "." + digits[i:]
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,190 @@
+package money
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/polyfloyd/dbmap"
+)
+
+func TestParse(t *testing.T) {
+	tt := []struct {
+		Decimal string
+		Scale   int
+		Minor   int64
+		Error   bool
+	}{
+		{"12.34", 2, 1234, false},
+		{"12.3400", 2, 1234, false},
+		{"12.3", 2, 1230, false},
+		{"12", 2, 1200, false},
+		{".5", 2, 50, false},
+		{"-0.05", 2, -5, false},
+		{"+1.00", 2, 100, false},
+		{"12.34", 0, 0, true},
+		{"12.345", 2, 0, true},
+		{"1.2.3", 2, 0, true},
+		{"abc", 2, 0, true},
+		{"", 2, 0, true},
+		{"-", 2, 0, true},
+		{"92233720368547758.08", 2, 0, true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Decimal, func(t *testing.T) {
+			minor, err := Parse(tc.Decimal, tc.Scale)
+			if tc.Error {
+				if err == nil {
+					t.Fatalf("expected an error, got %d", minor)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if minor != tc.Minor {
+				t.Fatalf("unexpected minor units, exp %d, got %d", tc.Minor, minor)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tt := []struct {
+		Minor   int64
+		Scale   int
+		Decimal string
+	}{
+		{1234, 2, "12.34"},
+		{5, 2, "0.05"},
+		{-5, 2, "-0.05"},
+		{-1234, 2, "-12.34"},
+		{0, 2, "0.00"},
+		{1234, 0, "1234"},
+		{1234, 4, "0.1234"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Decimal, func(t *testing.T) {
+			if decimal := Format(tc.Minor, tc.Scale); decimal != tc.Decimal {
+				t.Fatalf("unexpected decimal, exp %q, got %q", tc.Decimal, decimal)
+			}
+		})
+	}
+}
+
+func TestMapping(t *testing.T) {
+	type Cents int64
+	type MyStruct struct {
+		Decimal Cents
+		Integer Cents
+	}
+
+	mapper, err := NewMapper(reflect.TypeOf(Cents(0)), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbmap.RegisterMapper(mapper)
+
+	rows := &dbmap.TestRows{
+		Current: -1,
+		Rows: []dbmap.TestRow{
+			{"decimal": "12.34", "integer": int64(1234)},
+		},
+	}
+
+	mapping, err := dbmap.StructMapping(MyStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := mapping.ScanAll(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slice := results.([]MyStruct)
+
+	if slice[0].Decimal != 1234 {
+		t.Fatalf("Decimal field was not scanned: %d", slice[0].Decimal)
+	}
+	if slice[0].Integer != 1234 {
+		t.Fatalf("Integer field was not scanned: %d", slice[0].Integer)
+	}
+
+	value, err := Decimal{Minor: int64(slice[0].Decimal), Scale: 2}.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "12.34" {
+		t.Fatalf("unexpected value, exp %q, got %#v", "12.34", value)
+	}
+}
+
+func TestMappingNull(t *testing.T) {
+	type NullableCents int64
+	type MyStruct struct {
+		Amount *NullableCents
+	}
+
+	mapper, err := NewMapper(reflect.TypeOf(NullableCents(0)), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbmap.RegisterMapper(mapper)
+
+	rows := &dbmap.TestRows{
+		Current: -1,
+		Rows: []dbmap.TestRow{
+			{"amount": "12.34"},
+			{"amount": nil},
+		},
+	}
+
+	mapping, err := dbmap.StructMapping(MyStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := mapping.ScanAll(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slice := results.([]MyStruct)
+
+	if slice[0].Amount == nil || *slice[0].Amount != 1234 {
+		t.Fatalf("Amount field was not scanned: %v", slice[0].Amount)
+	}
+	if slice[1].Amount != nil {
+		t.Fatalf("NULL Amount field was not scanned as nil: %v", *slice[1].Amount)
+	}
+}
+
+func TestMappingNullNotNullable(t *testing.T) {
+	type NotNullableCents int64
+	type MyStruct struct {
+		Amount NotNullableCents
+	}
+
+	mapper, err := NewMapper(reflect.TypeOf(NotNullableCents(0)), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbmap.RegisterMapper(mapper)
+
+	rows := &dbmap.TestRows{
+		Current: -1,
+		Rows: []dbmap.TestRow{
+			{"amount": nil},
+		},
+	}
+
+	mapping, err := dbmap.StructMapping(MyStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mapping.ScanAll(rows); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestNewMapperInvalidType(t *testing.T) {
+	if _, err := NewMapper(reflect.TypeOf(""), 2); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
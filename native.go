@@ -7,10 +7,12 @@ import (
 )
 
 func init() {
-	RegisterMapper(nativeMapper{})
-	RegisterMapper(sqlScannerMapper{})
+	RegisterMapperPriority(nativeMapper{}, PriorityNative)
+	RegisterMapperPriority(sqlScannerMapper{}, PrioritySQLScanner)
 }
 
+var timeType = reflect.TypeOf(time.Time{})
+
 type nativeMapper struct{}
 
 func (nativeMapper) Accepts(fieldType reflect.Type) bool {
@@ -27,10 +29,9 @@ func (nativeMapper) Accepts(fieldType reflect.Type) bool {
 		return true
 	}
 
-	if fieldType.ConvertibleTo(reflect.TypeOf(time.Time{})) {
-		return true
-	}
-	if fieldType.ConvertibleTo(reflect.TypeOf(&time.Time{})) {
+	// Only time.Time itself is accepted, not types defined from it like
+	// `type MyTime time.Time`. Those require a mapper to be registered.
+	if fieldType == timeType || fieldType == reflect.PtrTo(timeType) {
 		return true
 	}
 	if fieldType.ConvertibleTo(reflect.TypeOf([]byte{})) {
@@ -45,6 +46,8 @@ func (nativeMapper) Receive(field reflect.Value) (receiver interface{}) {
 
 func (nativeMapper) Copy(target, scanned interface{}) {}
 
+// sqlScannerMapper maps all types implementing sql.Scanner, including the
+// generic sql.Null[T] wrapper. The field itself is used as the receiver.
 type sqlScannerMapper struct{}
 
 func (sqlScannerMapper) Accepts(fieldType reflect.Type) bool {
@@ -0,0 +1,100 @@
+package dbmap
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNativeMapperTime(t *testing.T) {
+	// Types defined from time.Time are convertible to it, but are not
+	// accepted by the native mapper.
+	type myTime time.Time
+
+	tt := []struct {
+		Value  interface{}
+		Accept bool
+	}{
+		{time.Time{}, true},
+		{&time.Time{}, true},
+		{myTime{}, false},
+		{&myTime{}, false},
+	}
+	for _, tc := range tt {
+		typ := reflect.TypeOf(tc.Value)
+		t.Run(typ.String(), func(t *testing.T) {
+			if !typ.ConvertibleTo(timeType) && !typ.ConvertibleTo(reflect.PtrTo(timeType)) {
+				t.Fatalf("%v is expected to be convertible to a time", typ)
+			}
+			if accept := (nativeMapper{}).Accepts(typ); accept != tc.Accept {
+				t.Fatalf("unexpected acceptance of %v, exp %v, got %v", typ, tc.Accept, accept)
+			}
+		})
+	}
+}
+
+func TestNativeMapperTimeDerived(t *testing.T) {
+	// Fields of types defined from time.Time are not supported by default.
+	type myTime time.Time
+	type MyStruct struct {
+		Created myTime `db:"created"`
+	}
+	if _, err := StructMapping(MyStruct{}); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestSQLNullMapping(t *testing.T) {
+	type MyStruct struct {
+		Name  sql.Null[string] `db:"name"`
+		Count sql.Null[int64]  `db:"count"`
+	}
+
+	rows := &TestRows{
+		Current: -1,
+		Rows: []TestRow{
+			{"name": "foo", "count": int64(42)},
+			{"name": nil, "count": nil},
+		},
+	}
+
+	mapping, err := StructMapping(MyStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"Name", "Count"} {
+		if _, ok := mapping.mapping[field].(sqlScannerMapper); !ok {
+			t.Fatalf("field %s is not mapped by the sql.Scanner mapper: %v", field, mapping)
+		}
+	}
+
+	results, err := mapping.ScanAll(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slice := results.([]MyStruct)
+
+	if !slice[0].Name.Valid || slice[0].Name.V != "foo" {
+		t.Fatalf("value Name was not scanned: %#v", slice[0].Name)
+	}
+	if !slice[0].Count.Valid || slice[0].Count.V != 42 {
+		t.Fatalf("value Count was not scanned: %#v", slice[0].Count)
+	}
+	if slice[1].Name.Valid || slice[1].Count.Valid {
+		t.Fatalf("NULL values were scanned as valid: %#v", slice[1])
+	}
+
+	for i, target := range slice {
+		for col, valuer := range map[string]driver.Valuer{"name": target.Name, "count": target.Count} {
+			value, err := valuer.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value != rows.Rows[i][col] {
+				t.Fatalf("value of %q at index %d does not round-trip, exp %#v, got %#v", col, i, rows.Rows[i][col], value)
+			}
+		}
+	}
+}
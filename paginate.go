@@ -0,0 +1,68 @@
+package dbmap
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// A Queryer is able to execute a query that returns rows, like *sql.DB,
+// *sql.Conn and *sql.Tx.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+var _ Queryer = &sql.DB{}
+
+type queryFunc func(ctx context.Context, query string, args ...interface{}) (Rows, error)
+
+// Paginate repeatedly executes the query to process a large result set in
+// pages of at most pageSize rows. The query is passed args followed by the
+// page size and the offset of the page, in that order, and should use the
+// last two for its LIMIT and OFFSET, e.g.:
+//
+//	SELECT id, name FROM users WHERE owner = $1 ORDER BY id LIMIT $2 OFFSET $3
+//
+// Each page is scanned into a slice of T and passed to fn. Pagination stops
+// once a page holds fewer than pageSize rows or when fn returns an error.
+func Paginate[T any](ctx context.Context, db Queryer, queryTemplate string, pageSize int, fn func([]T) error, args ...interface{}) error {
+	query := func(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		return rows, nil
+	}
+	return paginate(ctx, query, queryTemplate, pageSize, fn, args...)
+}
+
+func paginate[T any](ctx context.Context, query queryFunc, queryTemplate string, pageSize int, fn func([]T) error, args ...interface{}) error {
+	if pageSize <= 0 {
+		return fmt.Errorf("invalid page size: %d", pageSize)
+	}
+	mapping, err := structMappingOf[T]()
+	if err != nil {
+		return err
+	}
+
+	for offset := 0; ; offset += pageSize {
+		queryArgs := append(append(make([]interface{}, 0, len(args)+2), args...), pageSize, offset)
+		rows, err := query(ctx, queryTemplate, queryArgs...)
+		if err != nil {
+			return err
+		}
+		results, err := mapping.ScanAll(rows)
+		if err != nil {
+			return err
+		}
+		page := results.([]T)
+		if len(page) > 0 {
+			if err := fn(page); err != nil {
+				return err
+			}
+		}
+		if len(page) < pageSize {
+			return nil
+		}
+	}
+}
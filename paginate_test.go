@@ -0,0 +1,215 @@
+package dbmap
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+)
+
+func init() {
+	sql.Register("dbmap-paginate", paginateDriver{})
+}
+
+// paginateDriver is a fake database driver. The data source name is the
+// number of rows in its table, where even IDs are owned by "even" and odd IDs
+// by "odd". Queries take the owner, limit and offset as arguments.
+type paginateDriver struct{}
+
+func (paginateDriver) Open(name string) (driver.Conn, error) {
+	numRows, err := strconv.Atoi(name)
+	if err != nil {
+		return nil, err
+	}
+	return paginateConn{numRows: numRows}, nil
+}
+
+type paginateConn struct {
+	numRows int
+}
+
+func (conn paginateConn) Prepare(query string) (driver.Stmt, error) {
+	return paginateStmt{numRows: conn.numRows}, nil
+}
+
+func (paginateConn) Close() error {
+	return nil
+}
+
+func (paginateConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported")
+}
+
+type paginateStmt struct {
+	numRows int
+}
+
+func (paginateStmt) Close() error {
+	return nil
+}
+
+func (paginateStmt) NumInput() int {
+	return 3
+}
+
+func (paginateStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("exec is not supported")
+}
+
+func (stmt paginateStmt) Query(args []driver.Value) (driver.Rows, error) {
+	owner, limit, offset := args[0].(string), args[1].(int64), args[2].(int64)
+	rows := &paginateRows{owner: owner}
+	for id := int64(0); id < int64(stmt.numRows); id++ {
+		if (id%2 == 0) != (owner == "even") {
+			continue
+		}
+		rows.ids = append(rows.ids, id)
+	}
+	rows.ids = rows.ids[min(offset, int64(len(rows.ids))):min(offset+limit, int64(len(rows.ids)))]
+	return rows, nil
+}
+
+type paginateRows struct {
+	ids   []int64
+	owner string
+}
+
+func (*paginateRows) Columns() []string {
+	return []string{"id", "owner"}
+}
+
+func (*paginateRows) Close() error {
+	return nil
+}
+
+func (rows *paginateRows) Next(dest []driver.Value) error {
+	if len(rows.ids) == 0 {
+		return io.EOF
+	}
+	dest[0], dest[1] = rows.ids[0], rows.owner
+	rows.ids = rows.ids[1:]
+	return nil
+}
+
+func TestPaginate(t *testing.T) {
+	type MyStruct struct {
+		ID int `db:"id"`
+	}
+
+	tt := []struct {
+		NumRows   int
+		PageSize  int
+		PageSizes []int
+	}{
+		{0, 2, []int{}},
+		{5, 2, []int{2, 2, 1}},
+		{4, 2, []int{2, 2}},
+		{3, 10, []int{3}},
+	}
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("%d/%d", tc.NumRows, tc.PageSize), func(t *testing.T) {
+			query := func(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+				if query != "SELECT id FROM foo LIMIT $1 OFFSET $2" {
+					t.Fatalf("unexpected query: %q", query)
+				}
+				limit, offset := args[0].(int), args[1].(int)
+				if limit != tc.PageSize {
+					t.Fatalf("unexpected limit, exp %d, got %d", tc.PageSize, limit)
+				}
+				rows := &TestRows{Current: -1}
+				for i := offset; i < offset+limit && i < tc.NumRows; i++ {
+					rows.Rows = append(rows.Rows, TestRow{"id": i})
+				}
+				return rows, nil
+			}
+
+			var pageSizes []int
+			var next int
+			err := paginate(context.Background(), query, "SELECT id FROM foo LIMIT $1 OFFSET $2", tc.PageSize, func(page []MyStruct) error {
+				pageSizes = append(pageSizes, len(page))
+				for _, elem := range page {
+					if elem.ID != next {
+						return fmt.Errorf("unexpected ID, exp %d, got %d", next, elem.ID)
+					}
+					next++
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(pageSizes) != fmt.Sprint(tc.PageSizes) {
+				t.Fatalf("unexpected page sizes, exp %v, got %v", tc.PageSizes, pageSizes)
+			}
+			if next != tc.NumRows {
+				t.Fatalf("unexpected number of rows, exp %d, got %d", tc.NumRows, next)
+			}
+		})
+	}
+}
+
+func TestPaginateDB(t *testing.T) {
+	type MyStruct struct {
+		ID    int64  `db:"id"`
+		Owner string `db:"owner"`
+	}
+
+	db, err := sql.Open("dbmap-paginate", "9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var pageSizes []int
+	var ids []int64
+	query := "SELECT id, owner FROM foo WHERE owner = $1 ORDER BY id LIMIT $2 OFFSET $3"
+	err = Paginate(context.Background(), db, query, 2, func(page []MyStruct) error {
+		pageSizes = append(pageSizes, len(page))
+		for _, elem := range page {
+			if elem.Owner != "odd" {
+				return fmt.Errorf("unexpected owner: %q", elem.Owner)
+			}
+			ids = append(ids, elem.ID)
+		}
+		return nil
+	}, "odd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(pageSizes) != "[2 2]" {
+		t.Fatalf("unexpected page sizes, exp [2 2], got %v", pageSizes)
+	}
+	if fmt.Sprint(ids) != "[1 3 5 7]" {
+		t.Fatalf("unexpected IDs, exp [1 3 5 7], got %v", ids)
+	}
+}
+
+func TestPaginateInvalid(t *testing.T) {
+	type MyStruct struct {
+		ID int `db:"id"`
+	}
+	fn := func(page []MyStruct) error {
+		t.Fatalf("unexpected page")
+		return nil
+	}
+
+	t.Run("type", func(t *testing.T) {
+		err := Paginate(context.Background(), nil, "SELECT id FROM foo LIMIT $1 OFFSET $2", 2, func(page []interface{}) error {
+			t.Fatalf("unexpected page")
+			return nil
+		})
+		if err == nil {
+			t.Fatalf("expected an error")
+		}
+	})
+	for _, pageSize := range []int{0, -1} {
+		t.Run(fmt.Sprintf("pageSize %d", pageSize), func(t *testing.T) {
+			if err := Paginate(context.Background(), nil, "SELECT id FROM foo LIMIT $1 OFFSET $2", pageSize, fn); err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
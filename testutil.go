@@ -25,7 +25,9 @@ func (row TestRow) Scan(data ...interface{}) error {
 		}
 
 		if scanner, ok := data[i].(sql.Scanner); ok {
-			scanner.Scan(row[col])
+			if err := scanner.Scan(row[col]); err != nil {
+				return fmt.Errorf("sql: Scan error on column index %d, name %q: %v", i, col, err)
+			}
 			continue
 		}
 		tar := reflect.Indirect(reflect.ValueOf(data[i]))